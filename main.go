@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"html/template"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -16,6 +19,10 @@ import (
 	"github.com/fatih/color"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/mattn/go-isatty"
+	texttemplate "text/template"
+
+	"github.com/guidiego/gest/reporter"
 )
 
 type TestEvent struct {
@@ -31,21 +38,29 @@ type SubTest struct {
 	Name   string
 	Passed bool
 	Time   float64
+	Output []string
 }
 
 type ParentTest struct {
 	Name     string
+	RawName  string
 	Subtests []SubTest
 	Passed   bool
+	Elapsed  float64
+	Output   []string
+	Flaky    bool
 }
 
 type PackageResult struct {
-	Name      string
-	Passed    bool
-	Skipped   bool
-	Duration  float64
-	ParentMap map[string]*ParentTest
-	HasTests  bool
+	Name         string
+	Passed       bool
+	Skipped      bool
+	Duration     float64
+	ParentMap    map[string]*ParentTest
+	HasTests     bool
+	OutputBuffer map[string][]string
+	Running      map[string]struct{}
+	StartedAt    time.Time
 }
 
 type FileCoverage struct {
@@ -53,6 +68,7 @@ type FileCoverage struct {
 	Covered   map[int]struct{}
 	Uncovered map[int]struct{}
 	Total     map[int]struct{}
+	Hits      map[int]int
 }
 
 type TreeNode struct {
@@ -95,9 +111,11 @@ func parseCoverProfile(path string) (map[string]*FileCoverage, error) {
 				Covered:   make(map[int]struct{}),
 				Uncovered: make(map[int]struct{}),
 				Total:     make(map[int]struct{}),
+				Hits:      make(map[int]int),
 			}
 		}
 		result[filePath].Total[lineNum] = struct{}{}
+		result[filePath].Hits[lineNum] += count
 		if count > 0 {
 			result[filePath].Covered[lineNum] = struct{}{}
 		} else {
@@ -173,6 +191,113 @@ func colorCoverage(coverage float64) text.Colors {
 	}
 }
 
+// badgeColor maps a coverage percentage to a shields.io-style hex color,
+// using the same thresholds as colorCoverage.
+func badgeColor(coverage float64) string {
+	switch {
+	case coverage < 20:
+		return "#e05d44"
+	case coverage < 50:
+		return "#dfb317"
+	case coverage < 70:
+		return "#a4a61d"
+	case coverage < 90:
+		return "#97ca00"
+	default:
+		return "#4c1"
+	}
+}
+
+const badgeSVGTemplate = `<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.Label}}: {{.Value}}">
+  <title>{{.Label}}: {{.Value}}</title>
+  <linearGradient id="s" x2="0" y2="100%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r">
+    <rect width="{{.Width}}" height="20" rx="3" fill="#fff"/>
+  </clipPath>
+  <g clip-path="url(#r)">
+    <rect width="{{.LabelWidth}}" height="20" fill="#555"/>
+    <rect x="{{.LabelWidth}}" width="{{.ValueWidth}}" height="20" fill="{{.Color}}"/>
+    <rect width="{{.Width}}" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" text-rendering="geometricPrecision" font-size="110">
+    <text aria-hidden="true" x="{{.LabelTextX}}" y="150" fill="#010101" fill-opacity=".3" transform="scale(.1)" textLength="{{.LabelTextLength}}">{{.Label}}</text>
+    <text x="{{.LabelTextX}}" y="140" transform="scale(.1)" textLength="{{.LabelTextLength}}">{{.Label}}</text>
+    <text aria-hidden="true" x="{{.ValueTextX}}" y="150" fill="#010101" fill-opacity=".3" transform="scale(.1)" textLength="{{.ValueTextLength}}">{{.Value}}</text>
+    <text x="{{.ValueTextX}}" y="140" transform="scale(.1)" textLength="{{.ValueTextLength}}">{{.Value}}</text>
+  </g>
+</svg>
+`
+
+// textWidth approximates the rendered width (in px, at 11px Verdana) of s,
+// the same per-character estimate shields.io badges use.
+func textWidth(s string) float64 {
+	return float64(len(s))*6.5 + 10
+}
+
+// renderBadge builds a shields.io-compatible "coverage: NN.N%" flat SVG
+// badge for coverage, colored by the same buckets as colorCoverage.
+func renderBadge(coverage float64) (string, error) {
+	label := "coverage"
+	value := fmt.Sprintf("%.1f%%", coverage)
+
+	labelWidth := textWidth(label)
+	valueWidth := textWidth(value)
+
+	data := struct {
+		Label, Value                     string
+		Color                            string
+		Width, LabelWidth, ValueWidth    float64
+		LabelTextX, ValueTextX           float64
+		LabelTextLength, ValueTextLength float64
+	}{
+		Label:           label,
+		Value:           value,
+		Color:           badgeColor(coverage),
+		Width:           labelWidth + valueWidth,
+		LabelWidth:      labelWidth,
+		ValueWidth:      valueWidth,
+		LabelTextX:      labelWidth / 2 * 10,
+		ValueTextX:      (labelWidth + valueWidth/2) * 10,
+		LabelTextLength: (labelWidth - 10) * 10,
+		ValueTextLength: (valueWidth - 10) * 10,
+	}
+
+	tmpl, err := texttemplate.New("badge").Parse(badgeSVGTemplate)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// colorDelta colors a Δ% coverage value: red for a regression, green for an
+// improvement, plain for no change.
+func colorDelta(delta float64) text.Colors {
+	switch {
+	case delta < 0:
+		return text.Colors{text.FgRed}
+	case delta > 0:
+		return text.Colors{text.FgHiGreen}
+	default:
+		return text.Colors{text.FgWhite}
+	}
+}
+
+// joinTreePath appends name to a "/"-joined path accumulated while walking a
+// TreeNode, matching the path keys parseCoverProfile/buildTree use.
+func joinTreePath(base, name string) string {
+	if base == "" {
+		return name
+	}
+	return base + "/" + name
+}
+
 func addRows(t table.Writer, node *TreeNode, depth int) {
 	indent := strings.Repeat("  ", depth)
 	name := node.Name
@@ -216,159 +341,1318 @@ func addRows(t table.Writer, node *TreeNode, depth int) {
 	}
 }
 
+// diffLines returns the line numbers whose covered state flipped between a
+// baseline and the current profile for a single file. baseline is nil when
+// the file didn't exist in the baseline profile.
+func diffLines(current, baseline *FileCoverage) (newlyCovered, newlyUncovered []int) {
+	if baseline == nil {
+		return nil, nil
+	}
+	for line := range current.Covered {
+		if _, wasUncovered := baseline.Uncovered[line]; wasUncovered {
+			newlyCovered = append(newlyCovered, line)
+		}
+	}
+	for line := range current.Uncovered {
+		if _, wasCovered := baseline.Covered[line]; wasCovered {
+			newlyUncovered = append(newlyUncovered, line)
+		}
+	}
+	sort.Ints(newlyCovered)
+	sort.Ints(newlyUncovered)
+	return newlyCovered, newlyUncovered
+}
+
+// addRowsDelta renders the same tree as addRows, with an extra Δ% coverage
+// column (against baseline) and newly-covered/newly-uncovered line lists
+// for files, computed by diffing fileData against baselineData.
+func addRowsDelta(t table.Writer, node, baseline *TreeNode, fileData, baselineData map[string]*FileCoverage, path string, depth int) {
+	indent := strings.Repeat("  ", depth)
+	name := node.Name
+	if node.IsDir && node.Name != "." {
+		name += "/"
+	}
+	displayName := name
+	if node.Name != "." {
+		displayName = indent + name
+	}
+	lines := fmt.Sprintf("%d/%d", node.Covered, node.Total)
+	coverage := fmt.Sprintf("%6.2f%%", node.Coverage)
+	color := colorCoverage(node.Coverage)
+
+	baseCoverage := 0.0
+	if baseline != nil {
+		baseCoverage = baseline.Coverage
+	}
+	delta := node.Coverage - baseCoverage
+	deltaColor := colorDelta(delta)
+	deltaStr := fmt.Sprintf("%+.2f%%", delta)
+
+	newlyCoveredStr, newlyUncoveredStr := "", ""
+	if !node.IsDir {
+		newlyCovered, newlyUncovered := diffLines(fileData[path], baselineData[path])
+		newlyCoveredStr = joinInts(newlyCovered)
+		newlyUncoveredStr = joinInts(newlyUncovered)
+	}
+
+	if node.Name != "." {
+		t.AppendRow(
+			table.Row{
+				color.Sprintf("%s", displayName),
+				color.Sprintf("%s", coverage),
+				deltaColor.Sprintf("%s", deltaStr),
+				color.Sprintf("%s", lines),
+				text.FgHiGreen.Sprintf("%s", newlyCoveredStr),
+				text.FgRed.Sprintf("%s", newlyUncoveredStr),
+			},
+		)
+	}
+
+	children := make([]*TreeNode, 0, len(node.Children))
+	for _, child := range node.Children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Name < children[j].Name
+	})
+	for _, child := range children {
+		var baseChild *TreeNode
+		if baseline != nil {
+			baseChild = baseline.Children[child.Name]
+		}
+		addRowsDelta(t, child, baseChild, fileData, baselineData, joinTreePath(path, child.Name), depth+1)
+	}
+}
+
+func joinInts(values []int) string {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = strconv.Itoa(v)
+	}
+	return strings.Join(strs, ",")
+}
+
+// hasFileChild reports whether node is a directory that directly contains
+// at least one source file — the granularity a Go package actually maps
+// to, as opposed to an intermediate roll-up directory like "github.com"
+// or "github.com/guidiego", which only ever has directory children.
+func hasFileChild(node *TreeNode) bool {
+	if !node.IsDir {
+		return false
+	}
+	for _, child := range node.Children {
+		if !child.IsDir {
+			return true
+		}
+	}
+	return false
+}
+
+
+// collectRegressions walks node and baseline in parallel, keyed by path, and
+// returns a description for every package whose coverage regressed by more
+// than threshold percentage points versus baseline.
+func collectRegressions(node, baseline *TreeNode, path string, threshold float64) []string {
+	var regressions []string
+	if baseline != nil && hasFileChild(node) {
+		delta := node.Coverage - baseline.Coverage
+		if delta < -threshold {
+			label := path
+			if label == "" {
+				label = "."
+			}
+			regressions = append(regressions, fmt.Sprintf(
+				"%s: %.2f%% -> %.2f%% (%+.2f%%)",
+				label, baseline.Coverage, node.Coverage, delta,
+			))
+		}
+	}
+	for name, child := range node.Children {
+		var baseChild *TreeNode
+		if baseline != nil {
+			baseChild = baseline.Children[name]
+		}
+		regressions = append(regressions, collectRegressions(child, baseChild, joinTreePath(path, name), threshold)...)
+	}
+	return regressions
+}
+
+// collectPackageCoverage walks node and returns the coverage percentage of
+// every package — a directory node that directly contains a source file,
+// not an intermediate roll-up like "github.com/guidiego" — keyed by its
+// path, the granularity `gest check --min-package-coverage` gates on.
+func collectPackageCoverage(node *TreeNode, path string, out map[string]float64) {
+	if !node.IsDir {
+		return
+	}
+	if path != "" && hasFileChild(node) {
+		out[path] = node.Coverage
+	}
+	for name, child := range node.Children {
+		collectPackageCoverage(child, joinTreePath(path, name), out)
+	}
+}
+
+// badgeCmd implements `gest badge`: render a coverage.svg badge from a cover
+// profile, with no dependency beyond text/template.
+func badgeCmd(args []string) {
+	fs := flag.NewFlagSet("badge", flag.ExitOnError)
+	coverProfile := fs.String("coverprofile", "", "Path to coverage profile")
+	out := fs.String("out", "", "Path to write the SVG badge")
+	fs.Parse(args)
+
+	if *coverProfile == "" || *out == "" {
+		fmt.Println("Error: gest badge requires --coverprofile and --out")
+		os.Exit(1)
+	}
+
+	fileData, err := parseCoverProfile(*coverProfile)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	tree := buildTree(fileData)
+	aggregate(tree)
+
+	svg, err := renderBadge(tree.Coverage)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, []byte(svg), 0o644); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Coverage badge (%.1f%%) written to %s\n", tree.Coverage, *out)
+}
+
+// checkCmd implements `gest check`: exit non-zero when total coverage, or
+// any package's coverage, falls below the given thresholds.
+func checkCmd(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	coverProfile := fs.String("coverprofile", "", "Path to coverage profile")
+	minCoverage := fs.Float64("min-coverage", 0, "Fail if total coverage is below N percent")
+	minPackageCoverage := fs.Float64("min-package-coverage", 0, "Fail if any package's coverage is below N percent")
+	fs.Parse(args)
+
+	if *coverProfile == "" {
+		fmt.Println("Error: gest check requires --coverprofile")
+		os.Exit(1)
+	}
+
+	fileData, err := parseCoverProfile(*coverProfile)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	tree := buildTree(fileData)
+	aggregate(tree)
+
+	ok := true
+	if tree.Coverage < *minCoverage {
+		color.New(color.FgRed).Printf("total coverage %.2f%% is below --min-coverage %.2f%%\n", tree.Coverage, *minCoverage)
+		ok = false
+	}
+
+	if *minPackageCoverage > 0 {
+		packageCoverage := make(map[string]float64)
+		collectPackageCoverage(tree, "", packageCoverage)
+
+		names := make([]string, 0, len(packageCoverage))
+		for name := range packageCoverage {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			coverage := packageCoverage[name]
+			if coverage < *minPackageCoverage {
+				color.New(color.FgRed).Printf("package %s coverage %.2f%% is below --min-package-coverage %.2f%%\n", name, coverage, *minPackageCoverage)
+				ok = false
+			}
+		}
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+	color.New(color.FgGreen).Printf("coverage OK: %.2f%% total\n", tree.Coverage)
+}
+
+// LineAnnotation describes the coverage state of a single source line.
+type LineAnnotation struct {
+	Line         int    `json:"line"`
+	Text         string `json:"text"`
+	Hits         int    `json:"hits"`
+	Instrumented bool   `json:"instrumented"`
+	Covered      bool   `json:"covered"`
+}
+
+// FileReport is the per-file payload embedded in the HTML coverage report.
+type FileReport struct {
+	Path     string           `json:"path"`
+	Coverage float64          `json:"coverage"`
+	Covered  int              `json:"covered"`
+	Total    int              `json:"total"`
+	Lines    []LineAnnotation `json:"lines"`
+}
+
+// DirReport is an aggregated roll-up for a directory in the tree.
+type DirReport struct {
+	Path     string  `json:"path"`
+	Coverage float64 `json:"coverage"`
+	Covered  int     `json:"covered"`
+	Total    int     `json:"total"`
+}
+
+// CoverageReport is the JSON blob embedded in coverage.html (and also
+// written standalone as coverage.json) so the viewer can filter and sort
+// without any server-side support.
+type CoverageReport struct {
+	Files []FileReport `json:"files"`
+	Dirs  []DirReport  `json:"dirs"`
+}
+
+// buildFileReports reads every source file referenced in the cover profile
+// and annotates each of its lines as covered, uncovered or not-instrumented.
+// Files that can no longer be read (e.g. deleted since the profile was
+// recorded) are skipped rather than failing the whole report.
+func buildFileReports(fileData map[string]*FileCoverage) []FileReport {
+	paths := make([]string, 0, len(fileData))
+	for path := range fileData {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	reports := make([]FileReport, 0, len(paths))
+	for _, path := range paths {
+		fc := fileData[path]
+		src, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		lines := strings.Split(string(src), "\n")
+		annotations := make([]LineAnnotation, len(lines))
+		for i, text := range lines {
+			lineNum := i + 1
+			_, instrumented := fc.Total[lineNum]
+			_, covered := fc.Covered[lineNum]
+			annotations[i] = LineAnnotation{
+				Line:         lineNum,
+				Text:         text,
+				Hits:         fc.Hits[lineNum],
+				Instrumented: instrumented,
+				Covered:      covered,
+			}
+		}
+
+		covered := len(fc.Covered)
+		total := len(fc.Total)
+		coverage := 0.0
+		if total > 0 {
+			coverage = float64(covered) / float64(total) * 100
+		}
+
+		reports = append(reports, FileReport{
+			Path:     path,
+			Coverage: coverage,
+			Covered:  covered,
+			Total:    total,
+			Lines:    annotations,
+		})
+	}
+	return reports
+}
+
+// flattenDirReports walks the tree built by buildTree/aggregate and collects
+// a roll-up entry for every directory node, keyed by its path from root.
+func flattenDirReports(node *TreeNode, path string, out *[]DirReport) {
+	if !node.IsDir {
+		return
+	}
+	if path != "" {
+		*out = append(*out, DirReport{
+			Path:     path,
+			Coverage: node.Coverage,
+			Covered:  node.Covered,
+			Total:    node.Total,
+		})
+	}
+	for name, child := range node.Children {
+		flattenDirReports(child, joinTreePath(path, name), out)
+	}
+}
+
+const coverageHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Coverage Report</title>
+<style>
+  body { font-family: -apple-system, Helvetica, Arial, sans-serif; margin: 0; color: #1a1a1a; }
+  header { padding: 1rem 1.5rem; background: #1a1a1a; color: #fff; }
+  header h1 { margin: 0; font-size: 1.1rem; }
+  .layout { display: flex; align-items: flex-start; }
+  nav { width: 320px; padding: 1rem; box-sizing: border-box; border-right: 1px solid #ddd; }
+  nav input { width: 100%; box-sizing: border-box; padding: .4rem; margin-bottom: .5rem; }
+  main { flex: 1; padding: 1rem 1.5rem; overflow-x: auto; }
+  ul.tree, ul.tree ul { list-style: none; margin: 0; padding-left: 1rem; }
+  ul.tree { padding-left: 0; }
+  .tree button { background: none; border: none; cursor: pointer; font: inherit; padding: .1rem 0; text-align: left; }
+  .tree .file-covered { color: #1a7f37; }
+  .tree .file-warn { color: #9a6700; }
+  .tree .file-bad { color: #cf222e; }
+  table.summary { border-collapse: collapse; width: 100%; margin-bottom: 1.5rem; }
+  table.summary th, table.summary td { border-bottom: 1px solid #eee; padding: .3rem .6rem; text-align: left; cursor: pointer; }
+  pre.source { display: none; font-size: .85rem; line-height: 1.4; border: 1px solid #ddd; padding: .5rem 0; }
+  pre.source.active { display: block; }
+  .line { display: flex; }
+  .line .no { width: 3.5rem; flex: none; text-align: right; color: #999; user-select: none; padding-right: .75rem; }
+  .line .text { white-space: pre; }
+  .line.covered { background: #e6ffed; }
+  .line.uncovered { background: #ffeef0; }
+  .line.not-instrumented .text { color: #999; }
+</style>
+</head>
+<body>
+<header><h1>Coverage Report</h1></header>
+<div class="layout">
+  <nav>
+    <input id="filter" type="text" placeholder="Filter by path or min %">
+    {{.Tree}}
+  </nav>
+  <main>
+    <table class="summary" id="summary">
+      <thead><tr><th data-sort="path">File</th><th data-sort="coverage">Coverage</th></tr></thead>
+      <tbody></tbody>
+    </table>
+    <div id="sources">
+      {{range .Files}}
+      <pre class="source" id="src-{{.Path}}">{{range .Lines}}<div class="line {{if not .Instrumented}}not-instrumented{{else if .Covered}}covered{{else}}uncovered{{end}}"><span class="no">{{.Line}}</span><span class="text">{{.Text}}</span></div>{{end}}</pre>
+      {{end}}
+    </div>
+  </main>
+</div>
+<script id="coverage-data" type="application/json">{{.JSON}}</script>
+<script>
+  const data = JSON.parse(document.getElementById('coverage-data').textContent);
+  const tbody = document.querySelector('#summary tbody');
+  let sortKey = 'path';
+
+  function render(threshold, query) {
+    tbody.innerHTML = '';
+    const rows = data.files
+      .filter(f => f.coverage >= threshold)
+      .filter(f => f.path.toLowerCase().includes(query))
+      .sort((a, b) => sortKey === 'coverage' ? a.coverage - b.coverage : a.path.localeCompare(b.path));
+    for (const f of rows) {
+      const tr = document.createElement('tr');
+      const link = document.createElement('a');
+      link.href = '#';
+      link.textContent = f.path;
+      link.onclick = (e) => { e.preventDefault(); showFile(f.path); };
+      const tdPath = document.createElement('td');
+      tdPath.appendChild(link);
+      const tdCov = document.createElement('td');
+      tdCov.textContent = f.coverage.toFixed(2) + '%';
+      tr.appendChild(tdPath);
+      tr.appendChild(tdCov);
+      tbody.appendChild(tr);
+    }
+  }
+
+  function showFile(path) {
+    document.querySelectorAll('pre.source.active').forEach(e => e.classList.remove('active'));
+    const el = document.getElementById('src-' + path);
+    if (el) { el.classList.add('active'); el.scrollIntoView({block: 'nearest'}); }
+  }
+
+  document.querySelectorAll('#summary th').forEach(th => {
+    th.addEventListener('click', () => { sortKey = th.dataset.sort; render(currentThreshold(), currentQuery()); });
+  });
+
+  function currentThreshold() {
+    const v = parseFloat(document.getElementById('filter').value);
+    return isNaN(v) ? 0 : v;
+  }
+  function currentQuery() {
+    const v = document.getElementById('filter').value;
+    return isNaN(parseFloat(v)) ? v.toLowerCase() : '';
+  }
+
+  document.getElementById('filter').addEventListener('input', () => render(currentThreshold(), currentQuery()));
+  document.querySelectorAll('.tree button[data-file]').forEach(btn => {
+    btn.addEventListener('click', () => showFile(btn.dataset.file));
+  });
+
+  render(0, '');
+</script>
+</body>
+</html>
+`
+
+// renderTreeHTML builds the collapsible <ul>/<details> directory tree used
+// in the HTML report's sidebar, reusing the same TreeNode walked by addRows.
+func renderTreeHTML(node *TreeNode) string {
+	var b strings.Builder
+	renderTreeNode(&b, node, "")
+	return b.String()
+}
+
+func renderTreeNode(b *strings.Builder, node *TreeNode, path string) {
+	children := make([]*TreeNode, 0, len(node.Children))
+	for _, child := range node.Children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Name < children[j].Name
+	})
+
+	b.WriteString("<ul class=\"tree\">")
+	for _, child := range children {
+		childPath := joinTreePath(path, child.Name)
+		class := "file-bad"
+		switch {
+		case child.Coverage >= 80:
+			class = "file-covered"
+		case child.Coverage >= 50:
+			class = "file-warn"
+		}
+		if child.IsDir {
+			b.WriteString("<li><details open><summary>")
+			b.WriteString(template.HTMLEscapeString(child.Name))
+			fmt.Fprintf(b, " (%.1f%%)</summary>", child.Coverage)
+			renderTreeNode(b, child, childPath)
+			b.WriteString("</details></li>")
+		} else {
+			fmt.Fprintf(
+				b,
+				"<li><button class=\"%s\" data-file=\"%s\">%s (%.1f%%)</button></li>",
+				class,
+				template.HTMLEscapeString(childPath),
+				template.HTMLEscapeString(child.Name),
+				child.Coverage,
+			)
+		}
+	}
+	b.WriteString("</ul>")
+}
+
+// writeHTMLReport renders a self-contained HTML report to htmlPath
+// (reusing the tree already built for the table view) and a companion
+// coverage.json with the same data in the same directory, so CI can
+// consume either artifact.
+func writeHTMLReport(htmlPath string, tree *TreeNode, fileData map[string]*FileCoverage) error {
+	report := CoverageReport{Files: buildFileReports(fileData)}
+	flattenDirReports(tree, "", &report.Dirs)
+
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	jsonPath := filepath.Join(filepath.Dir(htmlPath), "coverage.json")
+	if err := os.WriteFile(jsonPath, payload, 0o644); err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("coverage").Parse(coverageHTMLTemplate)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(htmlPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return tmpl.Execute(out, struct {
+		Tree  template.HTML
+		Files []FileReport
+		JSON  template.JS
+	}{
+		Tree:  template.HTML(renderTreeHTML(tree)),
+		Files: report.Files,
+		JSON:  template.JS(payload),
+	})
+}
+
 func prettify(name string) string {
 	name = strings.ReplaceAll(name, "_", " ")
 	name = strings.ReplaceAll(name, "/", " > ")
 	return name
 }
 
-func printProgress(testsDone int) {
-	// Desenha uma barra de progresso simples
-	barLen := 20
-	filled := testsDone % (barLen + 1)
-	bar := strings.Repeat("■", filled) + strings.Repeat(" ", barLen-filled)
-	fmt.Printf("\rRunning tests: [%s] %d tests done", bar, testsDone)
+// reportSpec is one parsed --report flag occurrence, e.g. "junit=out.xml".
+type reportSpec struct {
+	Format string
+	Path   string
 }
 
-func main() {
-	coverProfile := flag.String("coverprofile", "", "Path to coverage profile")
-	flag.StringVar(coverProfile, "c", "", "Path to coverage profile (shorthand)")
-	flag.Parse()
+// reportFlags collects every --report flag occurrence; the flag is
+// repeatable so a single run can emit junit and cobertura side by side.
+type reportFlags []reportSpec
 
-	start := time.Now()
-	scanner := bufio.NewScanner(os.Stdin)
+func (r *reportFlags) String() string {
+	parts := make([]string, len(*r))
+	for i, spec := range *r {
+		parts[i] = spec.Format + "=" + spec.Path
+	}
+	return strings.Join(parts, ",")
+}
 
-	packages := make(map[string]*PackageResult)
-	suitesPassed, suitesFailed, suitesSkipped := 0, 0, 0
-	testsPassed, testsFailed := 0, 0
-	testsDone := 0
+func (r *reportFlags) Set(value string) error {
+	format, path, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("invalid --report value %q, expected format=path", value)
+	}
+	*r = append(*r, reportSpec{Format: format, Path: path})
+	return nil
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		var event TestEvent
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
-			continue
+// buildReporterResult flattens gest's package/parent/subtest grouping and
+// the parsed cover profile into the shape the reporter package writes.
+func buildReporterResult(packages map[string]*PackageResult, fileData map[string]*FileCoverage) reporter.Result {
+	pkgNames := make([]string, 0, len(packages))
+	for name := range packages {
+		pkgNames = append(pkgNames, name)
+	}
+	sort.Strings(pkgNames)
+
+	var result reporter.Result
+	for _, name := range pkgNames {
+		pkg := packages[name]
+		suite := reporter.Suite{
+			Package:  pkg.Name,
+			Passed:   pkg.Passed,
+			Skipped:  pkg.Skipped,
+			Duration: pkg.Duration,
+		}
+
+		parentNames := make([]string, 0, len(pkg.ParentMap))
+		for parentName := range pkg.ParentMap {
+			parentNames = append(parentNames, parentName)
 		}
+		sort.Strings(parentNames)
 
-		if _, ok := packages[event.Package]; !ok {
-			packages[event.Package] = &PackageResult{
-				Name:      event.Package,
-				ParentMap: make(map[string]*ParentTest),
+		for _, parentName := range parentNames {
+			pt := pkg.ParentMap[parentName]
+			if len(pt.Subtests) == 0 {
+				suite.Tests = append(suite.Tests, reporter.TestCase{
+					Name:    pt.Name,
+					Passed:  pt.Passed,
+					Elapsed: pt.Elapsed,
+					Output:  pt.Output,
+				})
+				continue
+			}
+			for _, st := range pt.Subtests {
+				suite.Tests = append(suite.Tests, reporter.TestCase{
+					Name:    pt.Name + "/" + st.Name,
+					Passed:  st.Passed,
+					Elapsed: st.Time,
+					Output:  st.Output,
+				})
 			}
 		}
+		result.Suites = append(result.Suites, suite)
+	}
+
+	paths := make([]string, 0, len(fileData))
+	for path := range fileData {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fc := fileData[path]
+		result.Coverage = append(result.Coverage, reporter.CoverageFile{
+			Path:     path,
+			LineHits: fc.Hits,
+			Covered:  len(fc.Covered),
+			Total:    len(fc.Total),
+		})
+	}
+
+	return result
+}
+
+// writeReports renders every --report spec using the reporter package.
+func writeReports(reports reportFlags, packages map[string]*PackageResult, fileData map[string]*FileCoverage) error {
+	if len(reports) == 0 {
+		return nil
+	}
+	result := buildReporterResult(packages, fileData)
+	for _, spec := range reports {
+		writer, err := reporter.WriterFor(spec.Format)
+		if err != nil {
+			return err
+		}
+		if err := writer.Write(spec.Path, result); err != nil {
+			return fmt.Errorf("writing %s report to %s: %w", spec.Format, spec.Path, err)
+		}
+	}
+	return nil
+}
 
-		if event.Test != "" {
-			packages[event.Package].HasTests = true
-			parts := strings.SplitN(event.Test, "/", 2)
-			parent := prettify(parts[0])
-			var subtestName string
-			isSub := false
-			if len(parts) == 2 {
-				subtestName = prettify(parts[1])
-				isSub = true
+// readEvents scans r for newline-delimited `go test -json` events and
+// streams them on the returned channel, so a consumer can update its state
+// (and redraw a live view) as each event arrives instead of waiting for
+// EOF. The channel is closed once r is exhausted.
+func readEvents(r io.Reader) <-chan TestEvent {
+	ch := make(chan TestEvent)
+	go func() {
+		defer close(ch)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		for scanner.Scan() {
+			var event TestEvent
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				continue
 			}
+			ch <- event
+		}
+	}()
+	return ch
+}
+
+// processEvent folds a single TestEvent into packages, the same grouping
+// main's stdin loop always used, so it can be shared between the direct
+// stdin pipeline and the `gest run` child-process pipeline.
+func processEvent(event TestEvent, packages map[string]*PackageResult) {
+	if _, ok := packages[event.Package]; !ok {
+		packages[event.Package] = &PackageResult{
+			Name:         event.Package,
+			ParentMap:    make(map[string]*ParentTest),
+			OutputBuffer: make(map[string][]string),
+			Running:      make(map[string]struct{}),
+			StartedAt:    time.Now(),
+		}
+	}
+	pkg := packages[event.Package]
 
-			if _, ok := packages[event.Package].ParentMap[parent]; !ok {
-				packages[event.Package].ParentMap[parent] = &ParentTest{Name: parent}
+	if event.Test != "" {
+		pkg.HasTests = true
+		parts := strings.SplitN(event.Test, "/", 2)
+		rawParent := parts[0]
+		parent := prettify(rawParent)
+		var subtestName string
+		isSub := false
+		if len(parts) == 2 {
+			subtestName = prettify(parts[1])
+			isSub = true
+		}
+
+		if _, ok := pkg.ParentMap[parent]; !ok {
+			pkg.ParentMap[parent] = &ParentTest{Name: parent, RawName: rawParent}
+		}
+
+		switch event.Action {
+		case "run":
+			pkg.Running[event.Test] = struct{}{}
+		case "output":
+			pkg.OutputBuffer[event.Test] = append(pkg.OutputBuffer[event.Test], event.Output)
+		case "pass", "fail":
+			delete(pkg.Running, event.Test)
+			output := pkg.OutputBuffer[event.Test]
+			delete(pkg.OutputBuffer, event.Test)
+			passed := event.Action == "pass"
+			if isSub {
+				pkg.ParentMap[parent].Subtests = append(
+					pkg.ParentMap[parent].Subtests,
+					SubTest{Name: subtestName, Passed: passed, Time: event.Elapsed, Output: output},
+				)
+				if !passed {
+					pkg.ParentMap[parent].Passed = false
+				}
+			} else {
+				pkg.ParentMap[parent].Passed = passed
+				pkg.ParentMap[parent].Elapsed = event.Elapsed
+				pkg.ParentMap[parent].Output = output
+			}
+		}
+	}
+
+	if event.Action == "pass" && event.Test == "" {
+		pkg.Passed = true
+		pkg.Duration = event.Elapsed
+	}
+	if event.Action == "fail" && event.Test == "" {
+		pkg.Passed = false
+		pkg.Duration = event.Elapsed
+	}
+}
+
+// ingest drains events from r into packages, redrawing live (when non-nil)
+// after every event that can change what the panel shows.
+func ingest(r io.Reader, packages map[string]*PackageResult, live *liveRenderer) {
+	for event := range readEvents(r) {
+		processEvent(event, packages)
+		if live != nil {
+			live.render(packages)
+		}
+	}
+	if live != nil {
+		live.finish()
+	}
+}
+
+// liveRenderer redraws a one-panel-per-package go-pretty table in place,
+// moving the cursor back up over its previous frame before each redraw.
+type liveRenderer struct {
+	lastLines int
+}
+
+func (lr *liveRenderer) render(packages map[string]*PackageResult) {
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf strings.Builder
+	t := table.NewWriter()
+	t.SetOutputMirror(&buf)
+	t.AppendHeader(table.Row{"Package", "Running", "Passed", "Failed", "Elapsed"})
+	for _, name := range names {
+		pkg := packages[name]
+		passed, failed := countPackageTests(pkg)
+		running := len(pkg.Running)
+		status := text.Colors{text.FgHiGreen}
+		if failed > 0 {
+			status = text.Colors{text.FgRed}
+		} else if running > 0 {
+			status = text.Colors{text.FgYellow}
+		}
+		elapsed := pkg.Duration
+		if pkg.Duration == 0 {
+			elapsed = time.Since(pkg.StartedAt).Seconds()
+		}
+		t.AppendRow(table.Row{
+			status.Sprintf("%s", name),
+			running,
+			passed,
+			failed,
+			fmt.Sprintf("%.2fs", elapsed),
+		})
+	}
+	t.SetStyle(table.StyleRounded)
+	t.Render()
+
+	frame := buf.String()
+	if lr.lastLines > 0 {
+		fmt.Printf("\x1b[%dA\x1b[J", lr.lastLines)
+	}
+	fmt.Println(frame)
+	lr.lastLines = strings.Count(frame, "\n") + 1
+}
+
+// finish clears the last drawn frame so the summary that follows prints on
+// a clean screen instead of leaving the final live table stuck above it.
+func (lr *liveRenderer) finish() {
+	if lr.lastLines > 0 {
+		fmt.Printf("\x1b[%dA\x1b[J", lr.lastLines)
+	}
+	lr.lastLines = 0
+}
+
+// countPackageTests counts top-level tests (a parent with no subtests
+// counts as one test; a parent with subtests counts each subtest) that
+// have finished, split by pass/fail.
+func countPackageTests(pkg *PackageResult) (passed, failed int) {
+	for _, pt := range pkg.ParentMap {
+		if len(pt.Subtests) == 0 {
+			if _, running := pkg.Running[pt.RawName]; running {
+				continue
+			}
+			if pt.Passed {
+				passed++
+			} else {
+				failed++
 			}
+			continue
+		}
+		for _, st := range pt.Subtests {
+			if st.Passed {
+				passed++
+			} else {
+				failed++
+			}
+		}
+	}
+	return passed, failed
+}
 
-			switch event.Action {
-			case "pass":
-				if isSub {
-					packages[event.Package].ParentMap[parent].Subtests = append(
-						packages[event.Package].ParentMap[parent].Subtests,
-						SubTest{Name: subtestName, Passed: true, Time: event.Elapsed},
-					)
-					testsPassed++
+// countTests totals pass/fail/flaky across every package, the way main
+// prints in its final summary. A flaky top-level test (one that failed at
+// least once but eventually passed under --rerun-failed) counts as passed
+// and flaky, not failed.
+func countTests(packages map[string]*PackageResult) (passed, failed, flaky int) {
+	for _, pkg := range packages {
+		for _, pt := range pkg.ParentMap {
+			if pt.Flaky {
+				total := 1
+				if len(pt.Subtests) > 0 {
+					total = len(pt.Subtests)
+				}
+				passed += total
+				flaky += total
+				continue
+			}
+			if len(pt.Subtests) == 0 {
+				if pt.Passed {
+					passed++
 				} else {
-					packages[event.Package].ParentMap[parent].Passed = true
-					testsPassed++
+					failed++
 				}
-				testsDone++
-				printProgress(testsDone)
-			case "fail":
-				if isSub {
-					packages[event.Package].ParentMap[parent].Subtests = append(
-						packages[event.Package].ParentMap[parent].Subtests,
-						SubTest{Name: subtestName, Passed: false, Time: event.Elapsed},
-					)
-					packages[event.Package].ParentMap[parent].Passed = false
-					testsFailed++
+				continue
+			}
+			for _, st := range pt.Subtests {
+				if st.Passed {
+					passed++
 				} else {
-					packages[event.Package].ParentMap[parent].Passed = false
-					testsFailed++
+					failed++
 				}
-				testsDone++
-				printProgress(testsDone)
 			}
 		}
+	}
+	return passed, failed, flaky
+}
 
-		// Detecta package pass/fail e duração
-		if event.Action == "pass" && event.Test == "" {
-			packages[event.Package].Passed = true
-			packages[event.Package].Duration = event.Elapsed
+// printPackageResults prints the plain colored package/test/subtest tree,
+// the view used whenever no --coverprofile table is rendered instead. A
+// parent marked Flaky (it failed at least once under --rerun-failed but
+// eventually passed) gets its own marker and color rather than looking
+// like a clean pass.
+func printPackageResults(packages map[string]*PackageResult) {
+	for _, pkg := range packages {
+		switch {
+		case pkg.Skipped:
+			color.New(color.FgYellow).Printf("%s  %s\n", color.New(color.Bold, color.BgYellow).Sprintf(" SKIP "), pkg.Name)
+		case pkg.Passed:
+			color.New(color.FgGreen).Printf(
+				"%s  %s (%.2fs)\n",
+				color.New(color.Bold, color.BgGreen).Sprintf(" PASS "),
+				pkg.Name,
+				pkg.Duration,
+			)
+		default:
+			color.New(color.FgRed).Printf(
+				"%s  %s (%.2fs)\n",
+				color.New(color.Bold, color.BgRed).Sprintf(" FAIL "),
+				pkg.Name,
+				pkg.Duration,
+			)
 		}
-		if event.Action == "fail" && event.Test == "" {
-			packages[event.Package].Passed = false
-			packages[event.Package].Duration = event.Elapsed
+		// Parent tests e subtests
+		for _, pt := range pkg.ParentMap {
+			switch {
+			case pt.Flaky:
+				color.New(color.FgYellow).Printf("  ~ %s (flaky)\n", pt.Name)
+			case pt.Passed:
+				color.New(color.FgGreen).Printf("  ✓ %s\n", pt.Name)
+			default:
+				color.New(color.FgRed).Printf("  ✗ %s\n", pt.Name)
+			}
+			for _, st := range pt.Subtests {
+				prefix, c := "     ✓", color.FgGreen
+				switch {
+				case pt.Flaky:
+					prefix, c = "     ~", color.FgYellow
+				case !st.Passed:
+					prefix, c = "     ✗", color.FgRed
+				}
+				color.New(c).Printf("%s %s\n", prefix, st.Name)
+			}
 		}
+		fmt.Println()
 	}
+}
 
-	// Limpa a barra de progresso
-	fmt.Print("\r" + strings.Repeat(" ", 60) + "\r")
+// fileLineRef matches a "file.go:123" reference the way Go's testing
+// package and panic stack traces format call sites.
+var fileLineRef = regexp.MustCompile(`[\w./-]+\.go:\d+`)
 
-	// Após ler tudo, ajusta SKIPPED e soma os resultados
-	for _, pkg := range packages {
-		if !pkg.HasTests {
-			pkg.Skipped = true
-			suitesSkipped++
-		} else if pkg.Passed {
-			suitesPassed++
-		} else {
-			suitesFailed++
+// highlightFileLines wraps every file:line reference in line with a bold
+// highlight so a failure excerpt's call sites stand out from the rest of
+// the captured output.
+func highlightFileLines(line string) string {
+	return fileLineRef.ReplaceAllStringFunc(line, func(ref string) string {
+		return color.New(color.Bold, color.FgCyan).Sprint(ref)
+	})
+}
+
+// uncoveredLinesFor looks up the FileCoverage entry whose path matches
+// filename by suffix (output only ever carries a bare or partial path,
+// never the full one parseCoverProfile keys by).
+func uncoveredLinesFor(filename string, fileData map[string]*FileCoverage) *FileCoverage {
+	for path, fc := range fileData {
+		if path == filename || strings.HasSuffix(path, "/"+filename) {
+			return fc
 		}
 	}
+	return nil
+}
 
-	if *coverProfile == "" {
-		// Print results agrupados
-		for _, pkg := range packages {
-			switch {
-			case pkg.Skipped:
-				color.New(color.FgYellow).Printf("%s  %s\n", color.New(color.Bold, color.BgYellow).Sprintf(" SKIP "), pkg.Name)
-			case pkg.Passed:
-				color.New(color.FgGreen).Printf(
-					"%s  %s (%.2fs)\n",
-					color.New(color.Bold, color.BgGreen).Sprintf(" PASS "),
-					pkg.Name,
-					pkg.Duration,
-				)
-			default:
-				color.New(color.FgRed).Printf(
-					"%s  %s (%.2fs)\n",
-					color.New(color.Bold, color.BgRed).Sprintf(" FAIL "),
-					pkg.Name,
-					pkg.Duration,
-				)
+// crossReferenceUncovered scans a failing test's captured output for
+// file:line references and, for every file that also appears in fileData,
+// reports its still-uncovered lines — the files most likely to hide the
+// root cause of the failure.
+func crossReferenceUncovered(output []string, fileData map[string]*FileCoverage) []string {
+	if fileData == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var notes []string
+	for _, line := range output {
+		for _, ref := range fileLineRef.FindAllString(line, -1) {
+			file, _, _ := strings.Cut(ref, ":")
+			if seen[file] {
+				continue
 			}
-			// Parent tests e subtests
-			for _, pt := range pkg.ParentMap {
-				if pt.Passed {
-					color.New(color.FgGreen).Printf("  ✓ %s\n", pt.Name)
-				} else {
-					color.New(color.FgRed).Printf("  ✗ %s\n", pt.Name)
+			seen[file] = true
+			fc := uncoveredLinesFor(file, fileData)
+			if fc == nil || len(fc.Uncovered) == 0 {
+				continue
+			}
+			lines := make([]int, 0, len(fc.Uncovered))
+			for l := range fc.Uncovered {
+				lines = append(lines, l)
+			}
+			sort.Ints(lines)
+			notes = append(notes, fmt.Sprintf("uncovered lines in %s: %s", fc.File, joinInts(lines)))
+		}
+	}
+	return notes
+}
+
+// printFailureOutput renders a Jest-style failure block for every test
+// that did not pass: its fully-qualified name, then its captured output
+// (capped at maxLines, 0 meaning unlimited) with file:line references
+// highlighted, then — when fileData is non-nil — any uncovered lines in
+// the files the failure output pointed at.
+func printFailureOutput(packages map[string]*PackageResult, fileData map[string]*FileCoverage, maxLines int) {
+	pkgNames := make([]string, 0, len(packages))
+	for name := range packages {
+		pkgNames = append(pkgNames, name)
+	}
+	sort.Strings(pkgNames)
+
+	printed := false
+	for _, pkgName := range pkgNames {
+		pkg := packages[pkgName]
+		parentNames := make([]string, 0, len(pkg.ParentMap))
+		for name := range pkg.ParentMap {
+			parentNames = append(parentNames, name)
+		}
+		sort.Strings(parentNames)
+
+		for _, parentName := range parentNames {
+			pt := pkg.ParentMap[parentName]
+			failures := []struct {
+				name   string
+				output []string
+			}{}
+			if pt.Flaky {
+				// Eventually passed under --rerun-failed; its stale
+				// subtest Passed=false isn't a real failure.
+			} else if len(pt.Subtests) == 0 {
+				if !pt.Passed {
+					failures = append(failures, struct {
+						name   string
+						output []string
+					}{pt.Name, pt.Output})
 				}
+			} else {
 				for _, st := range pt.Subtests {
-					prefix := "     ✓"
-					c := color.FgGreen
 					if !st.Passed {
-						prefix = "     ✗"
-						c = color.FgRed
+						failures = append(failures, struct {
+							name   string
+							output []string
+						}{pt.Name + " > " + st.Name, st.Output})
 					}
-					color.New(c).Printf("%s %s\n", prefix, st.Name)
 				}
 			}
-			fmt.Println()
+
+			for _, f := range failures {
+				if !printed {
+					color.New(color.Bold).Println("Failures:")
+					printed = true
+				}
+				color.New(color.FgRed, color.Bold).Printf("\n● %s › %s\n\n", pkg.Name, f.name)
+
+				output := f.output
+				truncated := 0
+				if maxLines > 0 && len(output) > maxLines {
+					truncated = len(output) - maxLines
+					output = output[:maxLines]
+				}
+				for _, line := range output {
+					fmt.Println("    " + highlightFileLines(strings.TrimRight(line, "\n")))
+				}
+				if truncated > 0 {
+					color.New(color.Faint).Printf("    ... %d more line(s) omitted (--max-output-lines)\n", truncated)
+				}
+
+				for _, note := range crossReferenceUncovered(f.output, fileData) {
+					color.New(color.FgYellow).Printf("    %s\n", note)
+				}
+			}
+		}
+	}
+	if printed {
+		fmt.Println()
+	}
+}
+
+// ensureGoTestJSON makes sure a `go test` invocation includes -json, so
+// gest run's child process always emits the event stream it expects, even
+// when the user wrote `gest run -- go test ./...` without it.
+func ensureGoTestJSON(args []string) []string {
+	if len(args) < 2 || args[0] != "go" || args[1] != "test" {
+		return args
+	}
+	for _, arg := range args[2:] {
+		if arg == "-json" || arg == "--json" {
+			return args
+		}
+	}
+	withJSON := make([]string, 0, len(args)+1)
+	withJSON = append(withJSON, args[0], args[1], "-json")
+	withJSON = append(withJSON, args[2:]...)
+	return withJSON
+}
+
+// runChildAndIngest execs args, streams its stdout (the JSON test events)
+// through the same ingestion pipeline stdin uses, and returns the final
+// per-package state once the child exits. The child's stderr is passed
+// straight through to gest's stderr, not ingested.
+func runChildAndIngest(args []string, live *liveRenderer) (map[string]*PackageResult, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	packages := make(map[string]*PackageResult)
+	ingest(stdout, packages, live)
+
+	// go test exits non-zero on test failure; that's not a gest error.
+	_ = cmd.Wait()
+	return packages, nil
+}
+
+// failingTopLevelTests groups, per package, the raw (un-prettified) names
+// of every top-level test that has not passed, the granularity `go test
+// -run` reruns at.
+func failingTopLevelTests(packages map[string]*PackageResult) map[string][]string {
+	failing := make(map[string][]string)
+	for pkgName, pkg := range packages {
+		for _, pt := range pkg.ParentMap {
+			if !pt.Passed {
+				failing[pkgName] = append(failing[pkgName], pt.RawName)
+			}
 		}
+	}
+	return failing
+}
+
+// rerunFlaky reruns each failing top-level test in isolation, up to
+// maxAttempts times, marking it Flaky (and Passed) the moment it passes on
+// a rerun. It mutates packages in place and returns the number of tests
+// that turned out to be flaky.
+func rerunFlaky(packages map[string]*PackageResult, maxAttempts int) int {
+	flaky := 0
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		failing := failingTopLevelTests(packages)
+		if len(failing) == 0 {
+			break
+		}
+		for pkgName, names := range failing {
+			quoted := make([]string, len(names))
+			for i, n := range names {
+				quoted[i] = regexp.QuoteMeta(n)
+			}
+			pattern := "^(" + strings.Join(quoted, "|") + ")$"
+			rerunArgs := []string{"go", "test", "-run", pattern, "-count=1", "-json", pkgName}
+
+			rerunPkgs, err := runChildAndIngest(rerunArgs, nil)
+			if err != nil {
+				continue
+			}
+			rerunPkg, ok := rerunPkgs[pkgName]
+			if !ok {
+				continue
+			}
+
+			for _, name := range names {
+				for _, rerunPt := range rerunPkg.ParentMap {
+					if rerunPt.RawName != name || !rerunPt.Passed {
+						continue
+					}
+					pt := packages[pkgName].ParentMap[prettify(name)]
+					if pt != nil && !pt.Passed {
+						pt.Passed = true
+						pt.Flaky = true
+						flaky++
+					}
+				}
+			}
+		}
+	}
+
+	for _, pkg := range packages {
+		stillFailing := false
+		for _, pt := range pkg.ParentMap {
+			if !pt.Passed {
+				stillFailing = true
+				break
+			}
+		}
+		if !stillFailing {
+			pkg.Passed = true
+		}
+	}
+
+	return flaky
+}
+
+// runCmd implements `gest run -- <command...>`: it spawns the command
+// itself (typically `go test ./...`) instead of only consuming piped
+// input, which is what makes --rerun-failed possible — gest now knows
+// exactly how to re-invoke a single failing test.
+func runCmd(args []string) {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	rerunFailed := fs.Int("rerun-failed", 0, "Rerun each failed top-level test up to N times, marking tests that eventually pass as flaky")
+	outputOnFailure := fs.Bool("output-on-failure", true, "Print captured test output for each failing test")
+	maxOutputLines := fs.Int("max-output-lines", 0, "Cap failure output dumps to N lines (0 means unlimited)")
+	fs.Parse(args)
+
+	childArgs := ensureGoTestJSON(fs.Args())
+	if len(childArgs) == 0 {
+		fmt.Println("Error: gest run requires a command, e.g. gest run -- go test ./...")
+		os.Exit(1)
+	}
+
+	start := time.Now()
+	live := &liveRenderer{}
+	packages, err := runChildAndIngest(childArgs, live)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	flaky := 0
+	if *rerunFailed > 0 {
+		flaky = rerunFlaky(packages, *rerunFailed)
+	}
+
+	suitesPassed, suitesFailed, suitesSkipped := summarizeSuites(packages)
+	testsPassed, testsFailed, _ := countTests(packages)
+
+	fmt.Println()
+	printPackageResults(packages)
+	if *outputOnFailure {
+		printFailureOutput(packages, nil, *maxOutputLines)
+	}
+	printSummary(suitesPassed, suitesFailed, suitesSkipped, testsPassed, testsFailed, flaky, time.Since(start).Seconds())
+}
+
+// summarizeSuites classifies every package as passed, failed or skipped
+// from its final state, the way main always did after stdin reached EOF.
+func summarizeSuites(packages map[string]*PackageResult) (passed, failed, skipped int) {
+	for _, pkg := range packages {
+		switch {
+		case !pkg.HasTests:
+			pkg.Skipped = true
+			skipped++
+		case pkg.Passed:
+			passed++
+		default:
+			failed++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// printSummary renders the final "Test Suites: / Tests: / Time:" block
+// shared by the classic stdin pipeline and `gest run`.
+func printSummary(suitesPassed, suitesFailed, suitesSkipped, testsPassed, testsFailed, testsFlaky int, totalTime float64) {
+	fmt.Print(text.Bold.Sprintf("Test Suites: "))
+	if suitesFailed > 0 {
+		fmt.Print(text.Colors{text.FgRed, text.Bold}.Sprintf("%d failed, ", suitesFailed))
+	}
+	if suitesPassed > 0 {
+		fmt.Print(text.Colors{text.FgHiGreen, text.Bold}.Sprintf("%d passed, ", suitesPassed))
+	}
+	if suitesSkipped > 0 {
+		fmt.Print(text.Colors{text.FgCyan, text.Bold}.Sprintf("%d skipped, ", suitesSkipped))
+	}
+	fmt.Print(text.Bold.Sprintf("%d total\n", suitesPassed+suitesFailed+suitesSkipped))
+
+	fmt.Print(text.Bold.Sprintf("Tests:       "))
+	if testsFailed > 0 {
+		fmt.Print(text.Colors{text.FgRed, text.Bold}.Sprintf("%d failed, ", testsFailed))
+	}
+	if testsFlaky > 0 {
+		fmt.Print(text.Colors{text.FgYellow, text.Bold}.Sprintf("%d flaky, ", testsFlaky))
+	}
+	if testsPassed > 0 {
+		fmt.Print(text.Colors{text.FgHiGreen, text.Bold}.Sprintf("%d passed, ", testsPassed))
+	}
+	fmt.Print(text.Bold.Sprintf("%d total\n", testsPassed+testsFailed))
+
+	fmt.Print(text.Bold.Sprintf("Time:        %.2fs\n", totalTime))
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "run":
+			runCmd(os.Args[2:])
+			return
+		case "badge":
+			badgeCmd(os.Args[2:])
+			return
+		case "check":
+			checkCmd(os.Args[2:])
+			return
+		}
+	}
+
+	coverProfile := flag.String("coverprofile", "", "Path to coverage profile")
+	flag.StringVar(coverProfile, "c", "", "Path to coverage profile (shorthand)")
+	htmlOut := flag.String("html", "", "Path to write a standalone HTML coverage report (requires --coverprofile)")
+	var reports reportFlags
+	flag.Var(&reports, "report", "Write a report in the given format, e.g. --report junit=report.xml (repeatable)")
+	baselineProfile := flag.String("baseline", "", "Path to a baseline coverage profile to diff against (requires --coverprofile)")
+	failUnderDelta := flag.Float64("fail-under-delta", -1, "Fail if any package's coverage regresses by more than N percentage points versus --baseline")
+	outputOnFailure := flag.Bool("output-on-failure", true, "Print captured test output for each failing test")
+	maxOutputLines := flag.Int("max-output-lines", 0, "Cap failure output dumps to N lines (0 means unlimited)")
+	flag.Parse()
+
+	start := time.Now()
+
+	var live *liveRenderer
+	if isatty.IsTerminal(os.Stdout.Fd()) {
+		live = &liveRenderer{}
+	}
+
+	packages := make(map[string]*PackageResult)
+	ingest(os.Stdin, packages, live)
+
+	suitesPassed, suitesFailed, suitesSkipped := summarizeSuites(packages)
+	testsPassed, testsFailed, _ := countTests(packages)
+
+	var fileData map[string]*FileCoverage
+
+	if *coverProfile == "" {
+		printPackageResults(packages)
 	} else {
 		for _, pkg := range packages {
 			var tag string
@@ -397,7 +1681,8 @@ func main() {
 		fmt.Println()
 		fmt.Println()
 
-		fileData, err := parseCoverProfile(*coverProfile)
+		var err error
+		fileData, err = parseCoverProfile(*coverProfile)
 		if err != nil {
 			fmt.Println("Error:", err)
 			os.Exit(1)
@@ -405,49 +1690,81 @@ func main() {
 		tree := buildTree(fileData)
 		aggregate(tree)
 
+		var baselineData map[string]*FileCoverage
+		var baselineTree *TreeNode
+		if *baselineProfile != "" {
+			var err error
+			baselineData, err = parseCoverProfile(*baselineProfile)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			baselineTree = buildTree(baselineData)
+			aggregate(baselineTree)
+		}
+
 		t := table.NewWriter()
 		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(
-			table.Row{
-				text.Bold.Sprint("File"),
-				text.Bold.Sprint("% Coverage"),
-				text.Bold.Sprint("% Lines"),
-				text.Bold.Sprint("Uncovered Lines #s"),
-			},
-		)
 
-		addRows(t, tree, 0)
+		if baselineTree == nil {
+			t.AppendHeader(
+				table.Row{
+					text.Bold.Sprint("File"),
+					text.Bold.Sprint("% Coverage"),
+					text.Bold.Sprint("% Lines"),
+					text.Bold.Sprint("Uncovered Lines #s"),
+				},
+			)
+			addRows(t, tree, 0)
+		} else {
+			t.AppendHeader(
+				table.Row{
+					text.Bold.Sprint("File"),
+					text.Bold.Sprint("% Coverage"),
+					text.Bold.Sprint("Δ vs Baseline"),
+					text.Bold.Sprint("% Lines"),
+					text.Bold.Sprint("Newly Covered"),
+					text.Bold.Sprint("Newly Uncovered"),
+				},
+			)
+			addRowsDelta(t, tree, baselineTree, fileData, baselineData, "", 0)
+		}
 
 		t.SetStyle(table.StyleRounded)
 		t.Style().Options.SeparateRows = false
 		t.Render()
-	}
 
-	fmt.Println()
-	fmt.Println()
+		if *htmlOut != "" {
+			if err := writeHTMLReport(*htmlOut, tree, fileData); err != nil {
+				fmt.Println("Error writing HTML report:", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\nHTML coverage report written to %s\n", *htmlOut)
+		}
 
-	// Summary
-	fmt.Print(text.Bold.Sprintf("Test Suites: "))
-	if suitesFailed > 0 {
-		fmt.Print(text.Colors{text.FgRed, text.Bold}.Sprintf("%d failed, ", suitesFailed))
-	}
-	if suitesPassed > 0 {
-		fmt.Print(text.Colors{text.FgHiGreen, text.Bold}.Sprintf("%d passed, ", suitesPassed))
-	}
-	if suitesSkipped > 0 {
-		fmt.Print(text.Colors{text.FgCyan, text.Bold}.Sprintf("%d skipped, ", suitesSkipped))
+		if baselineTree != nil && *failUnderDelta >= 0 {
+			regressions := collectRegressions(tree, baselineTree, "", *failUnderDelta)
+			if len(regressions) > 0 {
+				fmt.Println("\nCoverage regressed beyond the allowed delta:")
+				for _, r := range regressions {
+					fmt.Println("  " + r)
+				}
+				os.Exit(1)
+			}
+		}
 	}
-	fmt.Print(text.Bold.Sprintf("%d total\n", suitesPassed+suitesFailed+suitesSkipped))
 
-	fmt.Print(text.Bold.Sprintf("Tests:       "))
-	if testsFailed > 0 {
-		fmt.Print(text.Colors{text.FgRed, text.Bold}.Sprintf("%d failed, ", testsFailed))
+	if err := writeReports(reports, packages, fileData); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
 	}
-	if testsPassed > 0 {
-		fmt.Print(text.Colors{text.FgHiGreen, text.Bold}.Sprintf("%d passed, ", testsPassed))
+
+	if *outputOnFailure {
+		printFailureOutput(packages, fileData, *maxOutputLines)
 	}
-	fmt.Print(text.Bold.Sprintf("%d total\n", testsPassed+testsFailed))
 
-	totalTime := time.Since(start).Seconds()
-	fmt.Print(text.Bold.Sprintf("Time:        %.2fs\n", totalTime))
+	fmt.Println()
+	fmt.Println()
+
+	printSummary(suitesPassed, suitesFailed, suitesSkipped, testsPassed, testsFailed, 0, time.Since(start).Seconds())
 }
@@ -0,0 +1,117 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"os"
+	"path"
+	"sort"
+)
+
+type coberturaCoverage struct {
+	XMLName  xml.Name          `xml:"coverage"`
+	LineRate float64           `xml:"line-rate,attr"`
+	Packages coberturaPackages `xml:"packages"`
+}
+
+type coberturaPackages struct {
+	Packages []coberturaPackage `xml:"package"`
+}
+
+type coberturaPackage struct {
+	Name     string           `xml:"name,attr"`
+	LineRate float64          `xml:"line-rate,attr"`
+	Classes  coberturaClasses `xml:"classes"`
+}
+
+type coberturaClasses struct {
+	Classes []coberturaClass `xml:"class"`
+}
+
+type coberturaClass struct {
+	Name     string         `xml:"name,attr"`
+	Filename string         `xml:"filename,attr"`
+	LineRate float64        `xml:"line-rate,attr"`
+	Lines    coberturaLines `xml:"lines"`
+}
+
+type coberturaLines struct {
+	Lines []coberturaLine `xml:"line"`
+}
+
+type coberturaLine struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// CoberturaWriter renders a Result's coverage data as a Cobertura XML
+// document, grouping files into packages by directory the way `go test
+// -coverprofile` lays them out.
+type CoberturaWriter struct{}
+
+func (CoberturaWriter) Write(outPath string, result Result) error {
+	doc := coberturaCoverage{}
+
+	totalCovered, totalLines := 0, 0
+	byDir := make(map[string][]CoverageFile)
+	for _, f := range result.Coverage {
+		totalCovered += f.Covered
+		totalLines += f.Total
+		dir := path.Dir(f.Path)
+		byDir[dir] = append(byDir[dir], f)
+	}
+	if totalLines > 0 {
+		doc.LineRate = float64(totalCovered) / float64(totalLines)
+	}
+
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		files := byDir[dir]
+		sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+		pkg := coberturaPackage{Name: dir}
+		pkgCovered, pkgTotal := 0, 0
+		for _, f := range files {
+			pkgCovered += f.Covered
+			pkgTotal += f.Total
+
+			lineRate := 0.0
+			if f.Total > 0 {
+				lineRate = float64(f.Covered) / float64(f.Total)
+			}
+			class := coberturaClass{
+				Name:     path.Base(f.Path),
+				Filename: f.Path,
+				LineRate: lineRate,
+			}
+			for _, n := range sortedLineNumbers(f.LineHits) {
+				class.Lines.Lines = append(class.Lines.Lines, coberturaLine{Number: n, Hits: f.LineHits[n]})
+			}
+			pkg.Classes.Classes = append(pkg.Classes.Classes, class)
+		}
+		if pkgTotal > 0 {
+			pkg.LineRate = float64(pkgCovered) / float64(pkgTotal)
+		}
+		doc.Packages.Packages = append(doc.Packages.Packages, pkg)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	out = append([]byte(xml.Header), out...)
+	return os.WriteFile(outPath, out, 0o644)
+}
+
+func sortedLineNumbers(hits map[int]int) []int {
+	lines := make([]int, 0, len(hits))
+	for n := range hits {
+		lines = append(lines, n)
+	}
+	sort.Ints(lines)
+	return lines
+}
@@ -0,0 +1,69 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"os"
+	"strings"
+)
+
+type junitTestsuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestsuite `xml:"testsuite"`
+}
+
+type junitTestsuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     float64         `xml:"time,attr"`
+	Cases    []junitTestcase `xml:"testcase"`
+}
+
+type junitTestcase struct {
+	Name    string        `xml:"name,attr"`
+	Time    float64       `xml:"time,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+// JUnitWriter renders a Result as a JUnit-compatible <testsuites> document,
+// the format GitLab, Jenkins and GitHub Actions test reporters understand.
+type JUnitWriter struct{}
+
+func (JUnitWriter) Write(path string, result Result) error {
+	doc := junitTestsuites{}
+	for _, suite := range result.Suites {
+		ts := junitTestsuite{
+			Name: suite.Package,
+			Time: suite.Duration,
+		}
+		if suite.Skipped {
+			ts.Skipped = 1
+		}
+		for _, tc := range suite.Tests {
+			ts.Tests++
+			testcase := junitTestcase{Name: tc.Name, Time: tc.Elapsed}
+			if !tc.Passed {
+				ts.Failures++
+				testcase.Failure = &junitFailure{
+					Message: "test failed",
+					Content: strings.Join(tc.Output, "\n"),
+				}
+			}
+			ts.Cases = append(ts.Cases, testcase)
+		}
+		doc.Suites = append(doc.Suites, ts)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	out = append([]byte(xml.Header), out...)
+	return os.WriteFile(path, out, 0o644)
+}
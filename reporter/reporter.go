@@ -0,0 +1,56 @@
+// Package reporter renders gest's aggregated test and coverage results into
+// the machine-readable formats CI systems expect, independent of the
+// terminal rendering in package main.
+package reporter
+
+import "fmt"
+
+// TestCase is a single test or subtest result, flattened out of gest's
+// parent/subtest grouping so every Writer sees one flat list per suite.
+type TestCase struct {
+	Name    string
+	Passed  bool
+	Elapsed float64
+	Output  []string
+}
+
+// Suite groups every TestCase that ran in a single Go package.
+type Suite struct {
+	Package  string
+	Passed   bool
+	Skipped  bool
+	Duration float64
+	Tests    []TestCase
+}
+
+// CoverageFile is the per-file coverage data needed by coverage writers; it
+// mirrors main's FileCoverage without depending on it.
+type CoverageFile struct {
+	Path     string
+	LineHits map[int]int
+	Covered  int
+	Total    int
+}
+
+// Result bundles everything a Writer needs to render one report.
+type Result struct {
+	Suites   []Suite
+	Coverage []CoverageFile
+}
+
+// Writer renders a Result to disk in a specific format.
+type Writer interface {
+	Write(path string, result Result) error
+}
+
+// WriterFor resolves a --report format name (e.g. "junit") to its Writer.
+func WriterFor(format string) (Writer, error) {
+	switch format {
+	case "junit":
+		return JUnitWriter{}, nil
+	case "cobertura":
+		return CoberturaWriter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+}